@@ -1,9 +1,17 @@
 package mux
 
 import (
+	"bufio"
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"time"
 )
 
 // MiddlewareFunc is a function which receives an http.Handler and returns another http.Handler.
@@ -11,11 +19,71 @@ import (
 // to it, and then calls the handler passed as parameter to the MiddlewareFunc.
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// Logger is a pluggable sink for middleware diagnostics, so hosts that
+// standardize on log/slog, zap, zerolog, or similar can route middleware
+// logging through their own infrastructure instead of the standard log
+// package.
+type Logger interface {
+	// Log records that the named middleware executed for r.
+	Log(ctx context.Context, name string, r *http.Request)
+}
+
+// stdLogger is the default Logger, preserving the historical log.Printf
+// output of MiddlewareFuncWithLogging.
+type stdLogger struct{}
+
+func (stdLogger) Log(_ context.Context, name string, _ *http.Request) {
+	log.Printf("Executing middleware: %s", name)
+}
+
+// slogLogger adapts an *slog.Logger to the Logger interface, logging the
+// middleware name along with the request's method, path, and matched route
+// name as structured attributes.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that writes structured log entries through
+// logger, including the request method, path, and matched route alongside
+// the middleware name.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Log(ctx context.Context, name string, r *http.Request) {
+	attrs := []slog.Attr{
+		slog.String("middleware", name),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+	}
+	if route := CurrentRoute(r); route != nil {
+		if routeName := route.GetName(); routeName != "" {
+			attrs = append(attrs, slog.String("route", routeName))
+		} else if tmpl, err := route.GetPathTemplate(); err == nil {
+			attrs = append(attrs, slog.String("route", tmpl))
+		}
+	}
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "executing middleware", attrs...)
+}
+
+// routerLogger defers to r's configured Logger at call time, so middleware
+// registered before a SetMiddlewareLogger call still pick up the change.
+type routerLogger struct {
+	r *Router
+}
+
+func (l routerLogger) Log(ctx context.Context, name string, r *http.Request) {
+	l.r.middlewareLoggerOrDefault().Log(ctx, name, r)
+}
+
 // MiddlewareFuncWithLogging is a middleware function with optional logging.
 // It wraps a MiddlewareFunc and adds logging capabilities.
 type MiddlewareFuncWithLogging struct {
 	Handler MiddlewareFunc
 	Name    string
+	// Logger overrides the Logger used for this middleware. If nil, it
+	// defaults to stdLogger.
+	Logger Logger
 }
 
 // middleware interface is anything which implements a MiddlewareFunc named Middleware.
@@ -31,55 +99,494 @@ func (mw MiddlewareFunc) Middleware(handler http.Handler) http.Handler {
 // Middleware allows MiddlewareFuncWithLogging to implement the middleware interface.
 func (mw MiddlewareFuncWithLogging) Middleware(handler http.Handler) http.Handler {
 	return mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("Executing middleware: %s", mw.Name)
+		logger := mw.Logger
+		if logger == nil {
+			logger = stdLogger{}
+		}
+		logger.Log(r.Context(), mw.Name, r)
 		handler.ServeHTTP(w, r)
 	}))
 }
 
-// Use appends a MiddlewareFunc to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Router.
+// MiddlewarePriority orders middleware execution across a Router or Route
+// independent of the order in which Use was called. Middleware execute
+// outer-to-inner in ascending priority order: PriorityRecovery runs
+// outermost, then PriorityObservability, then PriorityAuth, then
+// PriorityHandler innermost, closest to the route handler. Within a single
+// tier, middleware retain their relative registration order.
+type MiddlewarePriority int
+
+const (
+	// PriorityRecovery is the outermost tier, intended for panic recovery.
+	PriorityRecovery MiddlewarePriority = iota
+	// PriorityObservability runs after PriorityRecovery, intended for
+	// access logging and metrics.
+	PriorityObservability
+	// PriorityAuth runs after PriorityObservability, intended for
+	// authentication and authorization.
+	PriorityAuth
+	// PriorityHandler is the innermost tier, closest to the route handler.
+	// Use and UseWithLogging register at this tier.
+	PriorityHandler
+)
+
+// prioritizedMiddleware pairs a middleware with the tier it was registered
+// at, so Router and Route can keep their middlewares slice sorted into a
+// well-defined outer-to-inner order regardless of Use call order.
+type prioritizedMiddleware struct {
+	mw       middleware
+	priority MiddlewarePriority
+}
+
+// MiddlewareGroup is a reusable stack of middleware that can be
+// shared across unrelated subrouters. With derives an extended copy
+// without mutating the receiver, enabling immutable composition of shared
+// stacks.
+type MiddlewareGroup struct {
+	entries []prioritizedMiddleware
+}
+
+// NewMiddlewareGroup creates an empty MiddlewareGroup.
+func NewMiddlewareGroup() *MiddlewareGroup {
+	return &MiddlewareGroup{}
+}
+
+// Use appends mwf to the group at PriorityHandler.
+func (g *MiddlewareGroup) Use(mwf ...MiddlewareFunc) {
+	g.UseWithPriority(PriorityHandler, mwf...)
+}
+
+// UseWithPriority appends mwf to the group at the given priority tier.
+func (g *MiddlewareGroup) UseWithPriority(priority MiddlewarePriority, mwf ...MiddlewareFunc) {
+	for _, fn := range mwf {
+		g.entries = append(g.entries, prioritizedMiddleware{mw: fn, priority: priority})
+	}
+}
+
+// With returns a copy of the group with mw appended at PriorityHandler,
+// leaving the receiver untouched so groups can be composed immutably, e.g.
+// a shared "base" group extended differently per subrouter.
+func (g *MiddlewareGroup) With(mw ...MiddlewareFunc) *MiddlewareGroup {
+	out := &MiddlewareGroup{entries: append([]prioritizedMiddleware(nil), g.entries...)}
+	out.Use(mw...)
+	return out
+}
+
+// sortMiddlewaresByPriority stable-sorts middlewares into ascending
+// priority order (outermost first), keeping the parallel priorities slice
+// in sync and preserving relative order within a tier.
+func sortMiddlewaresByPriority(middlewares []middleware, priorities []MiddlewarePriority) {
+	entries := make([]prioritizedMiddleware, len(middlewares))
+	for i, mw := range middlewares {
+		entries[i] = prioritizedMiddleware{mw: mw, priority: priorities[i]}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].priority < entries[j].priority
+	})
+
+	for i, e := range entries {
+		middlewares[i] = e.mw
+		priorities[i] = e.priority
+	}
+}
+
+// Use appends a MiddlewareFunc to the chain at PriorityHandler. Middleware can be used to intercept or otherwise modify requests and/or responses; within a priority tier they are executed in the order that they are applied to the Router, after any middleware registered at an outer tier (see MiddlewarePriority).
 func (r *Router) Use(mwf ...MiddlewareFunc) {
 	for _, fn := range mwf {
-		r.middlewares = append(r.middlewares, fn)
+		r.useWithPriority(PriorityHandler, fn)
+	}
+}
+
+// UseWithPriority appends mwf to the chain at the given priority tier. See
+// MiddlewarePriority for the outer-to-inner execution order this
+// guarantees regardless of the order Use/UseWithPriority were called in.
+func (r *Router) UseWithPriority(priority MiddlewarePriority, mwf ...MiddlewareFunc) {
+	for _, fn := range mwf {
+		r.useWithPriority(priority, fn)
 	}
 }
 
+// UseGroup registers every middleware in g against the Router, preserving
+// each entry's priority tier.
+func (r *Router) UseGroup(g *MiddlewareGroup) {
+	for _, e := range g.entries {
+		r.useWithPriority(e.priority, e.mw)
+	}
+}
+
+// Group calls fn with a fresh subrouter of r, so routes and middleware can
+// be declared together in a scoped block without affecting unrelated
+// subrouters. It mirrors the grouping pattern popularized by chi.
+func (r *Router) Group(fn func(*Router)) *Router {
+	sub := r.PathPrefix("").Subrouter()
+	fn(sub)
+	return sub
+}
+
+// useWithPriority appends mw to the chain at priority and re-sorts the
+// chain so execution order reflects tiers rather than registration order.
+func (r *Router) useWithPriority(priority MiddlewarePriority, mw middleware) {
+	r.middlewares = append(r.middlewares, mw)
+	r.middlewarePriorities = append(r.middlewarePriorities, priority)
+	sortMiddlewaresByPriority(r.middlewares, r.middlewarePriorities)
+}
+
 // UseWithLogging appends a MiddlewareFuncWithLogging to the chain, allowing optional logging.
 func (r *Router) UseWithLogging(name string, mw MiddlewareFunc) {
 	r.useInterface(MiddlewareFuncWithLogging{
 		Handler: mw,
 		Name:    name,
+		Logger:  routerLogger{r: r},
 	})
 }
 
-// useInterface appends a middleware to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Router.
+// SetMiddlewareLogger sets the Logger used by middleware registered through
+// UseWithLogging that have not been given their own Logger override. If
+// never called, middleware log through the standard library's log package,
+// preserving the historical behavior.
+func (r *Router) SetMiddlewareLogger(logger Logger) {
+	r.middlewareLogger = logger
+}
+
+// middlewareLoggerOrDefault returns r's configured Logger, falling back to
+// stdLogger if none has been set.
+func (r *Router) middlewareLoggerOrDefault() Logger {
+	if r.middlewareLogger != nil {
+		return r.middlewareLogger
+	}
+	return stdLogger{}
+}
+
+// useInterface appends a middleware to the chain at PriorityHandler. Middleware can be used to intercept or otherwise modify requests and/or responses; within a priority tier they are executed in the order that they are applied to the Router, after any middleware registered at an outer tier (see MiddlewarePriority).
 func (r *Router) useInterface(mw middleware) {
-	r.middlewares = append(r.middlewares, mw)
+	r.useWithPriority(PriorityHandler, mw)
 }
 
 // RouteMiddleware -------------------------------------------------------------
 
-// Use appends a MiddlewareFunc to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Route. Route middleware are executed after the Router middleware but before the Route handler.
+// Use appends a MiddlewareFunc to the chain at PriorityHandler. Middleware can be used to intercept or otherwise modify requests and/or responses; within a priority tier they are executed in the order that they are applied to the Route, after any middleware registered at an outer tier (see MiddlewarePriority). Route middleware are executed after the Router middleware but before the Route handler.
 func (r *Route) Use(mwf ...MiddlewareFunc) *Route {
 	for _, fn := range mwf {
-		r.middlewares = append(r.middlewares, fn)
+		r.useWithPriority(PriorityHandler, fn)
 	}
 
 	return r
 }
 
-// UseWithLogging appends a MiddlewareFuncWithLogging to the route's middleware chain.
+// UseWithPriority appends mwf to the route's middleware chain at the given
+// priority tier. See MiddlewarePriority for the outer-to-inner execution
+// order this guarantees regardless of call order.
+func (r *Route) UseWithPriority(priority MiddlewarePriority, mwf ...MiddlewareFunc) *Route {
+	for _, fn := range mwf {
+		r.useWithPriority(priority, fn)
+	}
+
+	return r
+}
+
+// UseGroup registers every middleware in g against the Route, preserving
+// each entry's priority tier.
+func (r *Route) UseGroup(g *MiddlewareGroup) *Route {
+	for _, e := range g.entries {
+		r.useWithPriority(e.priority, e.mw)
+	}
+
+	return r
+}
+
+// useWithPriority appends mw to the route's chain at priority and re-sorts
+// the chain so execution order reflects tiers rather than registration
+// order.
+func (r *Route) useWithPriority(priority MiddlewarePriority, mw middleware) {
+	r.middlewares = append(r.middlewares, mw)
+	r.middlewarePriorities = append(r.middlewarePriorities, priority)
+	sortMiddlewaresByPriority(r.middlewares, r.middlewarePriorities)
+}
+
+// UseWithLogging appends a MiddlewareFuncWithLogging to the route's
+// middleware chain, routed through the owning Router's configured Logger
+// (see Router.SetMiddlewareLogger), mirroring Router.UseWithLogging.
 func (r *Route) UseWithLogging(name string, mw MiddlewareFunc) *Route {
 	r.useInterface(MiddlewareFuncWithLogging{
 		Handler: mw,
 		Name:    name,
+		Logger:  routerLogger{r: r.router},
 	})
 
 	return r
 }
 
-// useInterface appends a middleware to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Route. Route middleware are executed after the Router middleware but before the Route handler.
+// useInterface appends a middleware to the chain at PriorityHandler. Middleware can be used to intercept or otherwise modify requests and/or responses; within a priority tier they are executed in the order that they are applied to the Route, after any middleware registered at an outer tier (see MiddlewarePriority). Route middleware are executed after the Router middleware but before the Route handler.
 func (r *Route) useInterface(mw middleware) {
-	r.middlewares = append(r.middlewares, mw)
+	r.useWithPriority(PriorityHandler, mw)
+}
+
+// UseForMethods appends mwf to the route's middleware chain, scoped to only
+// run for the given HTTP methods; requests with a different method skip
+// straight past each scoped middleware to the next one. This lets authors
+// attach middleware such as CSRF checks or cache-control headers that
+// should only apply to a subset of the methods a shared route handles.
+// Registration order relative to other route middleware (via Use) is
+// preserved, since each scoped middleware is just inserted into the normal
+// chain at PriorityHandler.
+func (r *Route) UseForMethods(methods []string, mwf ...MiddlewareFunc) *Route {
+	allowed := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		allowed[strings.ToUpper(m)] = struct{}{}
+	}
+
+	for _, fn := range mwf {
+		r.Use(methodScopedMiddleware(allowed, fn))
+	}
+
+	return r
+}
+
+// methodScopedMiddleware wraps mw so it only runs for requests whose method
+// is in allowed; other methods call next.ServeHTTP directly, bypassing mw.
+func methodScopedMiddleware(allowed map[string]struct{}, mw MiddlewareFunc) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if _, ok := allowed[req.Method]; !ok {
+				next.ServeHTTP(w, req)
+				return
+			}
+			wrapped.ServeHTTP(w, req)
+		})
+	}
+}
+
+// AccessLogEntry describes a single completed request, as reported to
+// AccessLogOptions.LogFunc.
+type AccessLogEntry struct {
+	Method   string
+	Path     string
+	Route    string
+	Status   int
+	Bytes    int
+	Duration time.Duration
+}
+
+// AccessLogOptions configures AccessLogMiddleware.
+type AccessLogOptions struct {
+	// LogFunc receives one AccessLogEntry per completed request. Defaults to
+	// a log.Printf-based formatter if nil.
+	LogFunc func(entry AccessLogEntry)
+}
+
+// AccessLogMiddleware returns a MiddlewareFunc that records the status
+// code, bytes written, and duration of each request and emits a single log
+// entry after the handler returns, including the matched route's name or
+// path template so operators can group requests by route rather than raw
+// path. The entry is still emitted, with whatever status/bytes the
+// recorder captured up to that point, if the handler panics; the panic is
+// then re-raised so an outer RecoveryMiddleware (per the documented
+// priority tiers) can still turn it into a response.
+func AccessLogMiddleware(opts AccessLogOptions) MiddlewareFunc {
+	logFunc := opts.LogFunc
+	if logFunc == nil {
+		logFunc = defaultAccessLog
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+
+			defer func() {
+				panicErr := recover()
+
+				entry := AccessLogEntry{
+					Method:   r.Method,
+					Path:     r.URL.Path,
+					Status:   rec.status,
+					Bytes:    rec.bytes,
+					Duration: time.Since(start),
+				}
+				if route := CurrentRoute(r); route != nil {
+					if routeName := route.GetName(); routeName != "" {
+						entry.Route = routeName
+					} else if tmpl, err := route.GetPathTemplate(); err == nil {
+						entry.Route = tmpl
+					}
+				}
+
+				logFunc(entry)
+
+				if panicErr != nil {
+					panic(panicErr)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+func defaultAccessLog(e AccessLogEntry) {
+	log.Printf("%s %s route=%q status=%d bytes=%d duration=%s", e.Method, e.Path, e.Route, e.Status, e.Bytes, e.Duration)
+}
+
+// responseRecorder wraps an http.ResponseWriter to capture the status code
+// and number of bytes written, while forwarding http.Flusher,
+// http.Hijacker, and http.Pusher support to the underlying writer so
+// streaming responses such as SSE or WebSocket upgrades keep working.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+func (rec *responseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("mux: ResponseWriter %T does not implement http.Hijacker", rec.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+func (rec *responseRecorder) Push(target string, opts *http.PushOptions) error {
+	p, ok := rec.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// PanicLogger is a pluggable sink for panic diagnostics, used by
+// RecoveryMiddleware. Unlike Logger, it keeps the panic value and stack
+// trace as distinct structured fields instead of flattening them into a
+// single name string.
+type PanicLogger interface {
+	// LogPanic records that err was recovered while handling r, along with
+	// the stack trace captured at the point of the panic.
+	LogPanic(ctx context.Context, err interface{}, stack []byte, r *http.Request)
+}
+
+// stdPanicLogger is the default PanicLogger, writing the panic value, the
+// request method and path, and the stack trace through log.Printf.
+type stdPanicLogger struct{}
+
+func (stdPanicLogger) LogPanic(_ context.Context, err interface{}, stack []byte, r *http.Request) {
+	log.Printf("panic recovered: %v [%s %s]\n%s", err, r.Method, r.URL.Path, stack)
+}
+
+// slogPanicLogger adapts an *slog.Logger to PanicLogger, logging the panic
+// value and stack trace as distinct attributes alongside the request's
+// method, path, and matched route name.
+type slogPanicLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogPanicLogger returns a PanicLogger that writes structured log
+// entries through logger.
+func NewSlogPanicLogger(logger *slog.Logger) PanicLogger {
+	return &slogPanicLogger{logger: logger}
+}
+
+func (l *slogPanicLogger) LogPanic(ctx context.Context, err interface{}, stack []byte, r *http.Request) {
+	attrs := []slog.Attr{
+		slog.Any("error", err),
+		slog.String("stack", string(stack)),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+	}
+	if route := CurrentRoute(r); route != nil {
+		if routeName := route.GetName(); routeName != "" {
+			attrs = append(attrs, slog.String("route", routeName))
+		} else if tmpl, err := route.GetPathTemplate(); err == nil {
+			attrs = append(attrs, slog.String("route", tmpl))
+		}
+	}
+	l.logger.LogAttrs(ctx, slog.LevelError, "panic recovered", attrs...)
+}
+
+// RecoveryOptions configures RecoveryMiddleware.
+type RecoveryOptions struct {
+	// Logger receives the recovered panic value and its stack trace as
+	// structured fields. Defaults to stdPanicLogger if nil.
+	Logger PanicLogger
+	// PanicHandler produces the response for a recovered panic. Defaults to
+	// writing a 500 response. It is not invoked if the handler had already
+	// written its response header before panicking, since the response is
+	// already committed at that point.
+	PanicHandler func(w http.ResponseWriter, r *http.Request, err interface{})
+}
+
+// RecoveryMiddleware returns a MiddlewareFunc that recovers from panics in
+// downstream handlers, logs the panic and its stack trace through the
+// configured PanicLogger, and invokes PanicHandler to produce a response.
+// http.ErrAbortHandler is re-panicked rather than recovered, per net/http
+// convention.
+func RecoveryMiddleware(opts RecoveryOptions) MiddlewareFunc {
+	logger := opts.Logger
+	if logger == nil {
+		logger = stdPanicLogger{}
+	}
+	panicHandler := opts.PanicHandler
+	if panicHandler == nil {
+		panicHandler = defaultPanicHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				logger.LogPanic(r.Context(), err, debug.Stack(), r)
+
+				if rec.wroteHeader {
+					// The handler already wrote a response before panicking;
+					// writing again would only produce net/http's
+					// superfluous WriteHeader log spam.
+					return
+				}
+
+				panicHandler(w, r, err)
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+func defaultPanicHandler(w http.ResponseWriter, _ *http.Request, _ interface{}) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
 // CORSMethodMiddleware automatically sets the Access-Control-Allow-Methods response header
@@ -0,0 +1,300 @@
+package mux
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// loggerFunc adapts a plain function to the Logger interface, mirroring
+// http.HandlerFunc.
+type loggerFunc func(ctx context.Context, name string, r *http.Request)
+
+func (f loggerFunc) Log(ctx context.Context, name string, r *http.Request) {
+	f(ctx, name, r)
+}
+
+func TestSetMiddlewareLoggerUsedByUseWithLogging(t *testing.T) {
+	var calls []string
+	logger := loggerFunc(func(_ context.Context, name string, _ *http.Request) {
+		calls = append(calls, name)
+	})
+
+	r := NewRouter()
+	r.SetMiddlewareLogger(logger)
+	r.UseWithLogging("router-mw", func(next http.Handler) http.Handler { return next })
+
+	route := r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {}).Name("home")
+	route.UseWithLogging("route-mw", func(next http.Handler) http.Handler { return next })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"router-mw", "route-mw"}
+	if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+		t.Fatalf("got %v, want %v; SetMiddlewareLogger was not used by UseWithLogging", calls, want)
+	}
+}
+
+func TestMiddlewareFuncWithLoggingOverrideTakesPrecedence(t *testing.T) {
+	var calls []string
+	defaultLogger := loggerFunc(func(_ context.Context, name string, _ *http.Request) {
+		calls = append(calls, "default:"+name)
+	})
+	overrideLogger := loggerFunc(func(_ context.Context, name string, _ *http.Request) {
+		calls = append(calls, "override:"+name)
+	})
+
+	r := NewRouter()
+	r.SetMiddlewareLogger(defaultLogger)
+	r.useInterface(MiddlewareFuncWithLogging{
+		Handler: func(next http.Handler) http.Handler { return next },
+		Name:    "custom",
+		Logger:  overrideLogger,
+	})
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"override:custom"}
+	if len(calls) != len(want) || calls[0] != want[0] {
+		t.Fatalf("got %v, want %v; per-middleware Logger override was not honored", calls, want)
+	}
+}
+
+func TestSlogLoggerIncludesRequestAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	var capturedReq *http.Request
+	r := NewRouter()
+	r.HandleFunc("/widgets", func(w http.ResponseWriter, req *http.Request) {
+		capturedReq = req
+	}).Name("widgets")
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	logger.Log(context.Background(), "auth", capturedReq)
+
+	out := buf.String()
+	for _, want := range []string{"middleware=auth", "method=GET", "path=/widgets", "route=widgets"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log output %q missing %q", out, want)
+		}
+	}
+}
+
+func recordingMiddleware(log *[]string, label string) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*log = append(*log, label)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouterUseOrdersByPriorityRegardlessOfCallOrder(t *testing.T) {
+	var got []string
+
+	r := NewRouter()
+	r.Use(recordingMiddleware(&got, "handler")) // registered first, but lowest priority
+	r.UseWithPriority(PriorityAuth, recordingMiddleware(&got, "auth"))
+	r.UseWithPriority(PriorityRecovery, recordingMiddleware(&got, "recovery"))
+	r.UseWithPriority(PriorityObservability, recordingMiddleware(&got, "observability"))
+
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"recovery", "observability", "auth", "handler"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v middleware calls, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got order %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMiddlewareGroupWithComposesImmutably(t *testing.T) {
+	var got []string
+
+	base := NewMiddlewareGroup()
+	base.UseWithPriority(PriorityRecovery, recordingMiddleware(&got, "recovery"))
+
+	withAuth := base.With(recordingMiddleware(&got, "auth"))
+
+	r := NewRouter()
+	r.UseGroup(withAuth)
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"recovery", "auth"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if len(base.entries) != 1 {
+		t.Fatalf("With mutated the base group: got %d entries, want 1", len(base.entries))
+	}
+}
+
+func TestUseForMethodsOnlyRunsForMatchingMethod(t *testing.T) {
+	var got []string
+
+	r := NewRouter()
+	route := r.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet, http.MethodPost, http.MethodOptions)
+	route.UseForMethods([]string{http.MethodPost, http.MethodPut}, recordingMiddleware(&got, "csrf"))
+
+	get := httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.ServeHTTP(httptest.NewRecorder(), get)
+	if len(got) != 0 {
+		t.Fatalf("GET should skip the scoped middleware, got %v", got)
+	}
+
+	post := httptest.NewRequest(http.MethodPost, "/items", nil)
+	r.ServeHTTP(httptest.NewRecorder(), post)
+	if len(got) != 1 || got[0] != "csrf" {
+		t.Fatalf("POST should run the scoped middleware, got %v", got)
+	}
+}
+
+func TestUseForMethodsDoesNotAffectCORSMethodUnion(t *testing.T) {
+	r := NewRouter()
+	route := r.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet, http.MethodPost, http.MethodOptions)
+	route.UseForMethods([]string{http.MethodPost}, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("scoped middleware should not run for OPTIONS preflight")
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Use(CORSMethodMiddleware(r))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, preflight)
+
+	allow := rec.Header().Get("Access-Control-Allow-Methods")
+	for _, m := range []string{http.MethodGet, http.MethodPost, http.MethodOptions} {
+		if !strings.Contains(allow, m) {
+			t.Fatalf("Access-Control-Allow-Methods %q missing method %s", allow, m)
+		}
+	}
+}
+
+type panicLoggerFunc func(ctx context.Context, err interface{}, stack []byte, r *http.Request)
+
+func (f panicLoggerFunc) LogPanic(ctx context.Context, err interface{}, stack []byte, r *http.Request) {
+	f(ctx, err, stack, r)
+}
+
+func TestRecoveryMiddlewarePassesStructuredPanicFields(t *testing.T) {
+	var gotErr interface{}
+	var gotStack []byte
+
+	logger := panicLoggerFunc(func(_ context.Context, err interface{}, stack []byte, _ *http.Request) {
+		gotErr = err
+		gotStack = stack
+	})
+
+	mw := RecoveryMiddleware(RecoveryOptions{Logger: logger})
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotErr != "boom" {
+		t.Fatalf("got err %v, want %q", gotErr, "boom")
+	}
+	if len(gotStack) == 0 || !strings.Contains(string(gotStack), "goroutine") {
+		t.Fatalf("stack trace was not passed as its own field: %q", gotStack)
+	}
+}
+
+func TestAccessLogMiddlewareStillLogsOnPanic(t *testing.T) {
+	var entries []AccessLogEntry
+
+	r := NewRouter()
+	r.UseWithPriority(PriorityRecovery, RecoveryMiddleware(RecoveryOptions{
+		Logger: panicLoggerFunc(func(context.Context, interface{}, []byte, *http.Request) {}),
+	}))
+	r.UseWithPriority(PriorityObservability, AccessLogMiddleware(AccessLogOptions{
+		LogFunc: func(e AccessLogEntry) { entries = append(entries, e) },
+	}))
+	r.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}).Name("boom")
+
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d access log entries for a panicking request, want 1", len(entries))
+	}
+	// The access log's own recorder is unwound (and thus captured) before
+	// the outer RecoveryMiddleware writes its 500 response, so it reflects
+	// whatever status was set at the moment of the panic: the default 200.
+	if entries[0].Route != "boom" || entries[0].Status != http.StatusOK {
+		t.Fatalf("got entry %+v, want route=boom status=%d", entries[0], http.StatusOK)
+	}
+}
+
+func TestRouterGroupScopesMiddlewareToSubrouter(t *testing.T) {
+	var got []string
+
+	r := NewRouter()
+	r.HandleFunc("/root", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.Group(func(sub *Router) {
+		sub.Use(recordingMiddleware(&got, "group"))
+		sub.HandleFunc("/scoped", func(w http.ResponseWriter, r *http.Request) {}).
+			Use(recordingMiddleware(&got, "scoped-route"))
+	})
+
+	rootReq := httptest.NewRequest(http.MethodGet, "/root", nil)
+	r.ServeHTTP(httptest.NewRecorder(), rootReq)
+	if len(got) != 0 {
+		t.Fatalf("unrelated root route should not run the group's middleware, got %v", got)
+	}
+
+	scopedReq := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+	r.ServeHTTP(httptest.NewRecorder(), scopedReq)
+
+	want := []string{"group", "scoped-route"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %v, want %v; Group middleware and the route's own Use should both run, in order", got, want)
+	}
+}
+
+func TestRouteMiddlewareInteractsWithPriority(t *testing.T) {
+	var got []string
+
+	r := NewRouter()
+	route := r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	route.Use(recordingMiddleware(&got, "route-handler"))
+	route.UseWithPriority(PriorityObservability, recordingMiddleware(&got, "route-observability"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"route-observability", "route-handler"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}